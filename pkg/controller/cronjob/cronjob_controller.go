@@ -0,0 +1,263 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	clientset "k8s.io/client-go/kubernetes"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
+	batchv1beta1listers "k8s.io/client-go/listers/batch/v1beta1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/features"
+)
+
+// Controller is responsible for creating and removing Jobs on behalf of CronJobs, by
+// comparing what is requested against what is actually running.
+type Controller struct {
+	kubeClient clientset.Interface
+	jobControl jobControlInterface
+	sjControl  sjControlInterface
+	recorder   record.EventRecorder
+
+	sjLister  batchv1beta1listers.CronJobLister
+	jobLister batchv1listers.JobLister
+}
+
+// NewController creates and initializes a new Controller.
+func NewController(kubeClient clientset.Interface, sjLister batchv1beta1listers.CronJobLister, jobLister batchv1listers.JobLister, recorder record.EventRecorder) *Controller {
+	registerMetrics()
+	return &Controller{
+		kubeClient: kubeClient,
+		jobControl: realJobControl{KubeClient: kubeClient},
+		sjControl:  realSJControl{KubeClient: kubeClient},
+		recorder:   recorder,
+		sjLister:   sjLister,
+		jobLister:  jobLister,
+	}
+}
+
+// syncAll lists all the CronJobs and Jobs in the cluster, groups the Jobs by the CronJob
+// that owns them, and calls syncOne on each CronJob.
+func (jm *Controller) syncAll() {
+	startTime := time.Now()
+	defer func() {
+		observeSyncAllWallTime(time.Since(startTime))
+	}()
+
+	sjs, err := jm.sjLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Error listing cronjobs: %v", err)
+		return
+	}
+
+	js, err := jm.jobLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Error listing jobs: %v", err)
+		return
+	}
+
+	jobsBySj := groupJobsByParent(toJobSlice(js))
+
+	for _, sj := range sjs {
+		jm.syncOne(sj.DeepCopy(), jobsBySj[sj.UID], time.Now())
+	}
+}
+
+// syncOne reconciles a single CronJob: it figures out which scheduled times have been
+// missed, starts a Job for the most recent one only (subject to concurrency policy and
+// starting deadline), and reaps Job references that no longer exist from Status.Active.
+func (jm *Controller) syncOne(sj *batchv1beta1.CronJob, js []batchv1.Job, now time.Time) {
+	startTime := time.Now()
+	defer func() {
+		observeSyncOneWallTime(time.Since(startTime))
+	}()
+
+	nameForLog := fmt.Sprintf("%s/%s", sj.Namespace, sj.Name)
+
+	childrenJobs := make(map[types.UID]bool)
+	for _, j := range js {
+		childrenJobs[j.ObjectMeta.UID] = true
+	}
+
+	// Remove any job reference from the active list if the corresponding job does not exist
+	// any more. Otherwise, the cronjob may be stuck in active mode forever even though there
+	// is no matching job running.
+	updatedSj := sj.DeepCopy()
+	updatedSj.Status.Active = nil
+	for _, ref := range sj.Status.Active {
+		if childrenJobs[ref.UID] {
+			updatedSj.Status.Active = append(updatedSj.Status.Active, ref)
+			continue
+		}
+		jm.recorder.Eventf(sj, v1.EventTypeNormal, "MissingJob", "Active job went missing: %v", ref.Name)
+	}
+	sj = updatedSj
+
+	if sj.DeletionTimestamp != nil {
+		jm.handleTermination(sj, js)
+		return
+	}
+
+	// Only CronJobs that explicitly opted into a non-default DeletionPolicy need the
+	// finalizer: it exists to enforce that policy at deletion time, and stamping it on
+	// every CronJob unconditionally would mean adding and immediately removing it again on
+	// the very next sync for the (overwhelmingly common) Orphan case, plus a rollback
+	// hazard where CronJobs could get stuck Terminating if the controller reverted.
+	needsDeletionPolicyFinalizer := utilfeature.DefaultFeatureGate.Enabled(features.CronJobsDeletionPolicy) &&
+		sj.Spec.DeletionPolicy != "" && sj.Spec.DeletionPolicy != batchv1beta1.DeletionPolicyOrphan
+	if needsDeletionPolicyFinalizer && !hasFinalizer(sj) {
+		sj.Finalizers = append(sj.Finalizers, cronJobChildCleanupFinalizer)
+		updated, err := jm.sjControl.Update(sj)
+		if err != nil {
+			klog.Errorf("Unable to add cleanup finalizer to %s: %v", nameForLog, err)
+			return
+		}
+		sj = updated
+	}
+
+	if sj.Spec.Suspend != nil && *sj.Spec.Suspend {
+		klog.V(4).Infof("Not starting job for %s because it is suspended", nameForLog)
+		return
+	}
+
+	times, err := getRecentUnmetScheduleTimes(*sj, now)
+	if err != nil {
+		jm.recorder.Eventf(sj, v1.EventTypeWarning, "FailedNeedsStart", "Cannot determine if job needs to be started: %v", err)
+		klog.Errorf("Cannot determine if %s needs to be started: %v", nameForLog, err)
+		return
+	}
+	if len(times) == 0 {
+		return
+	}
+	scheduledTime := times[len(times)-1]
+
+	tooLate := false
+	if sj.Spec.StartingDeadlineSeconds != nil {
+		tooLate = scheduledTime.Add(time.Second * time.Duration(*sj.Spec.StartingDeadlineSeconds)).Before(now)
+	}
+	if tooLate {
+		schedulingDecisionSkip.WithLabelValues(sj.Namespace, sj.Name, skipReasonMissedDeadline).Inc()
+		klog.V(4).Infof("Missed starting window for %s", nameForLog)
+		return
+	}
+
+	if sj.Spec.ConcurrencyPolicy == batchv1beta1.ForbidConcurrent && len(sj.Status.Active) > 0 {
+		schedulingDecisionSkip.WithLabelValues(sj.Namespace, sj.Name, skipReasonConcurrencyPolicy).Inc()
+		klog.V(4).Infof("Not starting %s because of prior execution still running and concurrency policy is Forbid", nameForLog)
+		return
+	}
+	if sj.Spec.ConcurrencyPolicy == batchv1beta1.ReplaceConcurrent {
+		for _, ref := range sj.Status.Active {
+			klog.V(4).Infof("Deleting job %s of %s that was still running at next scheduled start time", ref.Name, nameForLog)
+			if err := jm.jobControl.DeleteJob(sj.Namespace, ref.Name); err != nil {
+				jm.recorder.Eventf(sj, v1.EventTypeWarning, "FailedDelete", "Deleted job %s: %v", ref.Name, err)
+				klog.Errorf("Error deleting job %s from %s: %v", ref.Name, nameForLog, err)
+				return
+			}
+			jm.recorder.Eventf(sj, v1.EventTypeNormal, "SuccessfulDelete", "Deleted job %s", ref.Name)
+		}
+	}
+
+	jobReq, err := getJobFromTemplate(sj, scheduledTime)
+	if err != nil {
+		klog.Errorf("Unable to make Job from template in %s: %v", nameForLog, err)
+		return
+	}
+
+	jobResp, err := jm.jobControl.CreateJob(sj.Namespace, jobReq)
+	switch {
+	case apierrors.IsAlreadyExists(err):
+		// Either a previous sync of this CronJob already created this Job (e.g. the
+		// controller restarted between Job creation and the CronJob status update
+		// below), or its deterministic name happens to collide with some other Job.
+		// In the former case we can safely adopt the existing Job and carry on; this
+		// is the crux of making syncOne safe to replay against the same scheduled time.
+		existing, getErr := jm.jobControl.GetJob(sj.Namespace, jobReq.Name)
+		if getErr != nil {
+			jm.recorder.Eventf(sj, v1.EventTypeWarning, "FailedCreate", "Error creating job %s: %v", jobReq.Name, err)
+			klog.Errorf("Unable to fetch existing job %s for %s after AlreadyExists: %v", jobReq.Name, nameForLog, getErr)
+			return
+		}
+		if parentUID, found := getParentUIDFromJob(*existing); !found || parentUID != sj.UID {
+			jm.recorder.Eventf(sj, v1.EventTypeWarning, "FailedCreate", "Error creating job %s: job with this name already exists and is not owned by this cronjob", jobReq.Name)
+			klog.Errorf("Job %s already exists for %s but is not owned by it", jobReq.Name, nameForLog)
+			return
+		}
+		duplicateCreationSuppressed.WithLabelValues(sj.Namespace, sj.Name).Inc()
+		klog.V(2).Infof("Adopted existing job %s for %s after replaying scheduled time %v", existing.Name, nameForLog, scheduledTime)
+		jobResp = existing
+	case err != nil:
+		jm.recorder.Eventf(sj, v1.EventTypeWarning, "FailedCreate", "Error creating job %s: %v", jobReq.Name, err)
+		return
+	default:
+		klog.V(4).Infof("Created Job %s for %s", jobResp.Name, nameForLog)
+		jm.recorder.Eventf(sj, v1.EventTypeNormal, "SuccessfulCreate", "Created job %s", jobResp.Name)
+	}
+
+	schedulingDecisionInvoke.WithLabelValues(sj.Namespace, sj.Name).Inc()
+
+	if !inActiveList(*sj, jobResp.UID) {
+		sj.Status.Active = append(sj.Status.Active, v1.ObjectReference{
+			Kind:      "Job",
+			Namespace: jobResp.Namespace,
+			Name:      jobResp.Name,
+			UID:       jobResp.UID,
+		})
+	}
+	sj.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+	if _, err := jm.sjControl.UpdateStatus(sj); err != nil {
+		klog.Infof("Unable to update status for %s (rv = %s): %v", nameForLog, sj.ResourceVersion, err)
+	}
+}
+
+func inActiveList(sj batchv1beta1.CronJob, uid types.UID) bool {
+	for _, j := range sj.Status.Active {
+		if j.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// IsJobFinished returns whether a Job has completed, successfully or not.
+func IsJobFinished(j *batchv1.Job) bool {
+	for _, c := range j.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func toJobSlice(jobs []*batchv1.Job) []batchv1.Job {
+	out := make([]batchv1.Job, 0, len(jobs))
+	for _, j := range jobs {
+		out = append(out, *j)
+	}
+	return out
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/tools/record"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+	"k8s.io/kubernetes/pkg/features"
+)
+
+// fakeJobControl is an in-memory jobControlInterface for unit tests.
+type fakeJobControl struct {
+	Jobs       map[string]*batchv1.Job
+	CreateErr  error
+	DeletedJob []string
+}
+
+func newFakeJobControl() *fakeJobControl {
+	return &fakeJobControl{Jobs: map[string]*batchv1.Job{}}
+}
+
+var _ jobControlInterface = &fakeJobControl{}
+
+func (f *fakeJobControl) key(namespace, name string) string { return namespace + "/" + name }
+
+func (f *fakeJobControl) GetJob(namespace, name string) (*batchv1.Job, error) {
+	if j, ok := f.Jobs[f.key(namespace, name)]; ok {
+		return j, nil
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "jobs"}, name)
+}
+
+func (f *fakeJobControl) CreateJob(namespace string, job *batchv1.Job) (*batchv1.Job, error) {
+	if f.CreateErr != nil {
+		return nil, f.CreateErr
+	}
+	if _, exists := f.Jobs[f.key(namespace, job.Name)]; exists {
+		return nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "jobs"}, job.Name)
+	}
+	job = job.DeepCopy()
+	job.Namespace = namespace
+	job.UID = types.UID(job.Name)
+	f.Jobs[f.key(namespace, job.Name)] = job
+	return job, nil
+}
+
+func (f *fakeJobControl) UpdateJob(namespace string, job *batchv1.Job) (*batchv1.Job, error) {
+	f.Jobs[f.key(namespace, job.Name)] = job
+	return job, nil
+}
+
+func (f *fakeJobControl) DeleteJob(namespace, name string) error {
+	if _, ok := f.Jobs[f.key(namespace, name)]; !ok {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: "jobs"}, name)
+	}
+	delete(f.Jobs, f.key(namespace, name))
+	f.DeletedJob = append(f.DeletedJob, name)
+	return nil
+}
+
+// fakeSJControl is an in-memory sjControlInterface for unit tests.
+type fakeSJControl struct {
+	Updated *batchv1beta1.CronJob
+}
+
+var _ sjControlInterface = &fakeSJControl{}
+
+func (f *fakeSJControl) UpdateStatus(sj *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error) {
+	f.Updated = sj
+	return sj, nil
+}
+
+func (f *fakeSJControl) Update(sj *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error) {
+	f.Updated = sj
+	return sj, nil
+}
+
+func newTestController(jc *fakeJobControl, sc *fakeSJControl) *Controller {
+	return &Controller{
+		jobControl: jc,
+		sjControl:  sc,
+		recorder:   record.NewFakeRecorder(10),
+	}
+}
+
+// cronJobForSync returns a CronJob whose single missed schedule time lands exactly at now,
+// so syncOne has exactly one Job to create per call.
+func cronJobForSync(name string, now time.Time) *batchv1beta1.CronJob {
+	return &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns",
+			UID:               types.UID(name + "-uid"),
+			CreationTimestamp: metav1.Time{Time: now.Add(-time.Minute)},
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          "* * * * ?",
+			ConcurrencyPolicy: batchv1beta1.AllowConcurrent,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{},
+			},
+		},
+	}
+}
+
+func TestSyncOneCreateSuccess(t *testing.T) {
+	jc, sc := newFakeJobControl(), &fakeSJControl{}
+	jm := newTestController(jc, sc)
+
+	now := time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)
+	sj := cronJobForSync("sj", now)
+
+	jm.syncOne(sj, nil, now)
+
+	if len(jc.Jobs) != 1 {
+		t.Fatalf("expected 1 job to be created, got %d", len(jc.Jobs))
+	}
+	if sc.Updated == nil || len(sc.Updated.Status.Active) != 1 {
+		t.Fatalf("expected CronJob status to record 1 active job, got %+v", sc.Updated)
+	}
+}
+
+func TestSyncOneAlreadyExistsAdopt(t *testing.T) {
+	featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.CronJobsDeterministicJobNames, true)
+
+	jc, sc := newFakeJobControl(), &fakeSJControl{}
+	jm := newTestController(jc, sc)
+
+	now := time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)
+	sj := cronJobForSync("sj", now)
+
+	jobName := getJobName(sj, now)
+	existing := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            jobName,
+			Namespace:       sj.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*createJobControllerRef(sj)},
+		},
+	}
+	jc.Jobs[jc.key(sj.Namespace, jobName)] = existing
+	jc.CreateErr = apierrors.NewAlreadyExists(schema.GroupResource{Resource: "jobs"}, jobName)
+
+	jm.syncOne(sj, nil, now)
+
+	if sc.Updated == nil || len(sc.Updated.Status.Active) != 1 {
+		t.Fatalf("expected the existing job to be adopted into status.active, got %+v", sc.Updated)
+	}
+}
+
+func TestSyncOneAlreadyExistsWrongOwner(t *testing.T) {
+	featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.CronJobsDeterministicJobNames, true)
+
+	jc, sc := newFakeJobControl(), &fakeSJControl{}
+	jm := newTestController(jc, sc)
+
+	now := time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)
+	sj := cronJobForSync("sj", now)
+	other := cronJobForSync("other", now)
+
+	jobName := getJobName(sj, now)
+	existing := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            jobName,
+			Namespace:       sj.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*createJobControllerRef(other)},
+		},
+	}
+	jc.Jobs[jc.key(sj.Namespace, jobName)] = existing
+	jc.CreateErr = apierrors.NewAlreadyExists(schema.GroupResource{Resource: "jobs"}, jobName)
+
+	jm.syncOne(sj, nil, now)
+
+	if sc.Updated != nil {
+		t.Fatalf("expected no status update when the existing job belongs to a different CronJob, got %+v", sc.Updated)
+	}
+}
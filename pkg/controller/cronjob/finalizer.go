@@ -0,0 +1,103 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// cronJobChildCleanupFinalizer guards a CronJob from being removed by the API server until
+// its child Jobs have been handled according to Spec.DeletionPolicy. Without it, cleanup
+// relies solely on owner-reference garbage collection, which can leave Jobs orphaned if the
+// CronJob's UID-based ControllerRef is lost, and gives users no way to block CronJob deletion
+// until in-flight invocations finish.
+//
+// The controller only adds this finalizer to CronJobs that explicitly request a non-default
+// DeletionPolicy while the CronJobsDeletionPolicy feature gate is enabled; see syncOne. It never
+// touches CronJobs that rely on the default Orphan behavior.
+const cronJobChildCleanupFinalizer = "batch.kubernetes.io/cronjob-child-cleanup"
+
+func hasFinalizer(sj *batchv1beta1.CronJob) bool {
+	for _, f := range sj.Finalizers {
+		if f == cronJobChildCleanupFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(sj *batchv1beta1.CronJob) {
+	finalizers := make([]string, 0, len(sj.Finalizers))
+	for _, f := range sj.Finalizers {
+		if f != cronJobChildCleanupFinalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	sj.Finalizers = finalizers
+}
+
+// handleTermination is called instead of the normal scheduling path once a CronJob has a
+// DeletionTimestamp set. It applies Spec.DeletionPolicy to the CronJob's child Jobs and, once
+// that policy's precondition for removal is satisfied, drops cronJobChildCleanupFinalizer so
+// the API server can finish deleting the CronJob.
+//
+// Returns true once the finalizer has been removed and an Update of the CronJob has been issued
+// (an Update error is only logged). Returns false, without updating the CronJob, if it does not
+// carry the finalizer or if deletion is still blocked by the policy.
+func (jm *Controller) handleTermination(sj *batchv1beta1.CronJob, js []batchv1.Job) bool {
+	nameForLog := fmt.Sprintf("%s/%s", sj.Namespace, sj.Name)
+	schedulingDecisionSkip.WithLabelValues(sj.Namespace, sj.Name, skipReasonTerminating).Inc()
+
+	if !hasFinalizer(sj) {
+		return false
+	}
+
+	switch sj.Spec.DeletionPolicy {
+	case batchv1beta1.DeletionPolicyOrphan, "":
+		// Let owner-reference garbage collection (or nothing, if propagation is set to
+		// Orphan) decide the fate of the child Jobs; we only need to unblock deletion.
+
+	case batchv1beta1.DeletionPolicyWaitForCompletion:
+		for _, j := range js {
+			if !IsJobFinished(&j) {
+				klog.V(4).Infof("Waiting for job %s to complete before finalizing deletion of %s", j.Name, nameForLog)
+				return false
+			}
+		}
+
+	case batchv1beta1.DeletionPolicyBackground:
+		for _, j := range js {
+			if err := jm.jobControl.DeleteJob(j.Namespace, j.Name); err != nil && !apierrors.IsNotFound(err) {
+				jm.recorder.Eventf(sj, v1.EventTypeWarning, "FailedDelete", "Error deleting job %s during cronjob cleanup: %v", j.Name, err)
+				klog.Errorf("Error deleting job %s while finalizing deletion of %s: %v", j.Name, nameForLog, err)
+				return false
+			}
+		}
+	}
+
+	removeFinalizer(sj)
+	if _, err := jm.sjControl.Update(sj); err != nil {
+		klog.Errorf("Unable to remove cleanup finalizer from %s: %v", nameForLog, err)
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+	"k8s.io/kubernetes/pkg/features"
+)
+
+func cronJobWithFinalizer(policy batchv1beta1.CronJobDeletionPolicy) *batchv1beta1.CronJob {
+	return &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sj",
+			Namespace:  "ns",
+			Finalizers: []string{cronJobChildCleanupFinalizer},
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			DeletionPolicy: policy,
+		},
+	}
+}
+
+func finishedJob(name string) batchv1.Job {
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: v1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func runningJob(name string) batchv1.Job {
+	return batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+}
+
+func TestHandleTerminationOrphan(t *testing.T) {
+	jc, sc := newFakeJobControl(), &fakeSJControl{}
+	jm := newTestController(jc, sc)
+	sj := cronJobWithFinalizer(batchv1beta1.DeletionPolicyOrphan)
+
+	done := jm.handleTermination(sj, []batchv1.Job{runningJob("j1")})
+
+	if !done {
+		t.Fatalf("expected Orphan to unblock deletion immediately")
+	}
+	if hasFinalizer(sj) {
+		t.Fatalf("expected finalizer to be removed")
+	}
+	if len(jc.DeletedJob) != 0 {
+		t.Fatalf("Orphan must not delete child jobs, got %v", jc.DeletedJob)
+	}
+}
+
+func TestHandleTerminationBackground(t *testing.T) {
+	jc, sc := newFakeJobControl(), &fakeSJControl{}
+	jm := newTestController(jc, sc)
+	sj := cronJobWithFinalizer(batchv1beta1.DeletionPolicyBackground)
+	jc.Jobs[jc.key("ns", "j1")] = &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "j1", Namespace: "ns"}}
+
+	done := jm.handleTermination(sj, []batchv1.Job{runningJob("j1")})
+
+	if !done {
+		t.Fatalf("expected Background to unblock deletion once deletes are issued")
+	}
+	if hasFinalizer(sj) {
+		t.Fatalf("expected finalizer to be removed")
+	}
+	if len(jc.DeletedJob) != 1 || jc.DeletedJob[0] != "j1" {
+		t.Fatalf("expected job j1 to be deleted, got %v", jc.DeletedJob)
+	}
+}
+
+func TestHandleTerminationWaitForCompletionFinished(t *testing.T) {
+	jc, sc := newFakeJobControl(), &fakeSJControl{}
+	jm := newTestController(jc, sc)
+	sj := cronJobWithFinalizer(batchv1beta1.DeletionPolicyWaitForCompletion)
+
+	done := jm.handleTermination(sj, []batchv1.Job{finishedJob("j1")})
+
+	if !done {
+		t.Fatalf("expected WaitForCompletion to unblock deletion once all jobs have finished")
+	}
+	if hasFinalizer(sj) {
+		t.Fatalf("expected finalizer to be removed")
+	}
+}
+
+func TestHandleTerminationWaitForCompletionStillRunning(t *testing.T) {
+	jc, sc := newFakeJobControl(), &fakeSJControl{}
+	jm := newTestController(jc, sc)
+	sj := cronJobWithFinalizer(batchv1beta1.DeletionPolicyWaitForCompletion)
+
+	done := jm.handleTermination(sj, []batchv1.Job{runningJob("j1")})
+
+	if done {
+		t.Fatalf("expected WaitForCompletion to block deletion while a child job is still running")
+	}
+	if !hasFinalizer(sj) {
+		t.Fatalf("expected finalizer to remain while blocked")
+	}
+	if sc.Updated != nil {
+		t.Fatalf("expected no CronJob update while blocked, got %+v", sc.Updated)
+	}
+}
+
+func TestSyncOneDeletionPolicyFinalizerGating(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)
+
+	cases := []struct {
+		name          string
+		gateEnabled   bool
+		policy        batchv1beta1.CronJobDeletionPolicy
+		wantFinalizer bool
+	}{
+		{name: "gate disabled, Background requested", gateEnabled: false, policy: batchv1beta1.DeletionPolicyBackground, wantFinalizer: false},
+		{name: "gate enabled, Orphan is the default", gateEnabled: true, policy: batchv1beta1.DeletionPolicyOrphan, wantFinalizer: false},
+		{name: "gate enabled, policy unset", gateEnabled: true, policy: "", wantFinalizer: false},
+		{name: "gate enabled, Background requested", gateEnabled: true, policy: batchv1beta1.DeletionPolicyBackground, wantFinalizer: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.CronJobsDeletionPolicy, tc.gateEnabled)
+
+			jc, sc := newFakeJobControl(), &fakeSJControl{}
+			jm := newTestController(jc, sc)
+			sj := cronJobForSync("sj", now)
+			sj.Spec.DeletionPolicy = tc.policy
+
+			jm.syncOne(sj, nil, now)
+
+			// syncOne works on a copy of sj, so check the CronJob it persisted.
+			if sc.Updated == nil {
+				t.Fatalf("expected the CronJob to be persisted")
+			}
+			if hasFinalizer(sc.Updated) != tc.wantFinalizer {
+				t.Fatalf("expected finalizer present=%v, got finalizers=%v", tc.wantFinalizer, sc.Updated.Finalizers)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// jobControlInterface is an abstraction for manipulating Jobs, so that syncOne can be unit
+// tested against a fake without talking to a real API server.
+type jobControlInterface interface {
+	GetJob(namespace, name string) (*batchv1.Job, error)
+	CreateJob(namespace string, job *batchv1.Job) (*batchv1.Job, error)
+	UpdateJob(namespace string, job *batchv1.Job) (*batchv1.Job, error)
+	DeleteJob(namespace, name string) error
+}
+
+// realJobControl is the production jobControlInterface, backed by a real API client.
+type realJobControl struct {
+	KubeClient clientset.Interface
+}
+
+var _ jobControlInterface = &realJobControl{}
+
+func (r realJobControl) GetJob(namespace, name string) (*batchv1.Job, error) {
+	return r.KubeClient.BatchV1().Jobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (r realJobControl) CreateJob(namespace string, job *batchv1.Job) (*batchv1.Job, error) {
+	return r.KubeClient.BatchV1().Jobs(namespace).Create(context.TODO(), job, metav1.CreateOptions{})
+}
+
+func (r realJobControl) UpdateJob(namespace string, job *batchv1.Job) (*batchv1.Job, error) {
+	return r.KubeClient.BatchV1().Jobs(namespace).Update(context.TODO(), job, metav1.UpdateOptions{})
+}
+
+func (r realJobControl) DeleteJob(namespace, name string) error {
+	propagation := metav1.DeletePropagationBackground
+	return r.KubeClient.BatchV1().Jobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+// sjControlInterface is an abstraction for updating a CronJob and its status subresource, so
+// that syncOne can be unit tested against a fake without talking to a real API server.
+type sjControlInterface interface {
+	UpdateStatus(sj *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error)
+	// Update persists changes to the CronJob object itself, e.g. its finalizer list.
+	// Finalizers live on ObjectMeta, not Status, so they cannot go through UpdateStatus.
+	Update(sj *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error)
+}
+
+// realSJControl is the production sjControlInterface, backed by a real API client.
+type realSJControl struct {
+	KubeClient clientset.Interface
+}
+
+var _ sjControlInterface = &realSJControl{}
+
+func (r realSJControl) UpdateStatus(sj *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error) {
+	return r.KubeClient.BatchV1beta1().CronJobs(sj.Namespace).UpdateStatus(context.TODO(), sj, metav1.UpdateOptions{})
+}
+
+func (r realSJControl) Update(sj *batchv1beta1.CronJob) (*batchv1beta1.CronJob, error) {
+	return r.KubeClient.BatchV1beta1().CronJobs(sj.Namespace).Update(context.TODO(), sj, metav1.UpdateOptions{})
+}
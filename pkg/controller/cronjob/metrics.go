@@ -18,6 +18,9 @@ const (
 	skipReasonConcurrencyPolicy = "concurrencyPolicy"
 	skipReasonMissedDeadline    = "missedDeadline"
 	skipReasonError             = "error"
+	// skipReasonTerminating is recorded when a scheduling attempt lands on a CronJob that
+	// has a DeletionTimestamp set, whether or not it carries the cronjob-child-cleanup finalizer.
+	skipReasonTerminating = "terminating"
 )
 
 var schedulingDecisionInvoke = prometheus.NewCounterVec(
@@ -50,6 +53,13 @@ var jobFailed = prometheus.NewCounterVec(
 		Help:      "Counter that increments when the cronjob controller detects a child Job has completed with failure",
 	}, []string{namespaceKey, cronNameKey})
 
+var duplicateCreationSuppressed = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Subsystem: cronjobSubsystem,
+		Name:      "duplicate_creation_suppressed",
+		Help:      "Counter that increments when the cronjob controller replays a scheduled time, gets an AlreadyExists error creating the Job, and adopts the existing Job instead of treating it as a failure",
+	}, []string{namespaceKey, cronNameKey})
+
 var syncOneWallTimeGauge = prometheus.NewGauge(
 	prometheus.GaugeOpts{
 		Subsystem: cronjobSubsystem,
@@ -100,6 +110,7 @@ func registerMetrics() {
 		prometheus.MustRegister(schedulingDecisionSkip)
 		prometheus.MustRegister(jobSucceeded)
 		prometheus.MustRegister(jobFailed)
+		prometheus.MustRegister(duplicateCreationSuppressed)
 		prometheus.MustRegister(syncOneWallTimeGauge)
 		prometheus.MustRegister(syncOneWallTimeHistogram)
 		prometheus.MustRegister(syncAllWallTimeGauge)
@@ -0,0 +1,311 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cronjob contains a controller for CronJobs.
+package cronjob
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kubernetes/pkg/features"
+)
+
+// getJobFromTemplate makes a Job from a CronJob's JobTemplate for the given scheduled time and
+// returns it. The Job's name comes from getJobName, so it is derived from the scheduled time only
+// while the CronJobsDeterministicJobNames feature gate is enabled.
+func getJobFromTemplate(sj *batchv1beta1.CronJob, scheduledTime time.Time) (*batchv1.Job, error) {
+	labels := copyLabels(&sj.Spec.JobTemplate)
+	annotations := copyAnnotations(&sj.Spec.JobTemplate)
+	createdRef := createJobControllerRef(sj)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:          labels,
+			Annotations:     annotations,
+			Name:            getJobName(sj, scheduledTime),
+			OwnerReferences: []metav1.OwnerReference{*createdRef},
+		},
+	}
+	if err := applyJobMetadata(job, sj, scheduledTime); err != nil {
+		return nil, err
+	}
+	if err := scheme.Scheme.Convert(&sj.Spec.JobTemplate.Spec, &job.Spec, nil); err != nil {
+		return nil, fmt.Errorf("unable to convert job template: %v", err)
+	}
+	return job, nil
+}
+
+// reservedJobLabelKeys are labels the cronjob controller itself must set on every Job it
+// creates (in addition to the ControllerRef, which isn't a label at all). JobMetadata is
+// purely additive, so it is rejected outright if it tries to set one of these.
+var reservedJobLabelKeys = map[string]bool{
+	"controller-uid": true,
+	"job-name":       true,
+}
+
+// applyJobMetadata merges sj.Spec.JobMetadata's labels and annotations onto job, resolving the
+// {{.ScheduledTime}}, {{.CronJobName}}, {{.CronJobUID}} and {{.Namespace}} placeholders in their
+// values along the way. Keys already present on job (set from JobTemplate.ObjectMeta) take
+// precedence over JobMetadata, and keys the controller itself must own are rejected.
+func applyJobMetadata(job *batchv1.Job, sj *batchv1beta1.CronJob, scheduledTime time.Time) error {
+	jm := sj.Spec.JobMetadata
+	if jm == nil {
+		return nil
+	}
+
+	replacer := strings.NewReplacer(
+		"{{.ScheduledTime}}", scheduledTime.UTC().Format(time.RFC3339),
+		"{{.CronJobName}}", sj.Name,
+		"{{.CronJobUID}}", string(sj.UID),
+		"{{.Namespace}}", sj.Namespace,
+	)
+
+	for k, v := range jm.Labels {
+		if reservedJobLabelKeys[k] {
+			return fmt.Errorf("spec.jobMetadata.labels: cannot set reserved label %q, it is managed by the cronjob controller", k)
+		}
+		if job.Labels == nil {
+			job.Labels = map[string]string{}
+		}
+		if _, set := job.Labels[k]; set {
+			continue
+		}
+		job.Labels[k] = replacer.Replace(v)
+	}
+
+	for k, v := range jm.Annotations {
+		if job.Annotations == nil {
+			job.Annotations = map[string]string{}
+		}
+		if _, set := job.Annotations[k]; set {
+			continue
+		}
+		job.Annotations[k] = replacer.Replace(v)
+	}
+
+	return nil
+}
+
+// getJobName returns the name to give the Job created for the given scheduled time.
+//
+// When the CronJobsDeterministicJobNames feature gate is enabled, the name is derived
+// solely from the CronJob name and the scheduled time, so that replaying the same
+// scheduled time (e.g. after a controller crash between Job creation and CronJob status
+// update) always resolves to the same Job name. This lets callers treat an AlreadyExists
+// error from Job creation as a signal to adopt the existing Job rather than as a failure.
+// Existing CronJobs keep the old random-suffix behavior until the feature is enabled, so
+// that tooling which parses Job names does not break on upgrade.
+func getJobName(sj *batchv1beta1.CronJob, scheduledTime time.Time) string {
+	if utilfeature.DefaultFeatureGate.Enabled(features.CronJobsDeterministicJobNames) {
+		return fmt.Sprintf("%s-%d", sj.Name, scheduledTime.Unix())
+	}
+	return fmt.Sprintf("%s-%s", sj.Name, utilrand.String(5))
+}
+
+// getParentUIDFromJob extracts the UID of the CronJob that owns the given Job, if any.
+func getParentUIDFromJob(j batchv1.Job) (types.UID, bool) {
+	controllerRef := metav1.GetControllerOf(&j)
+	if controllerRef == nil {
+		return types.UID(""), false
+	}
+
+	if controllerRef.Kind != "CronJob" {
+		return types.UID(""), false
+	}
+
+	return controllerRef.UID, true
+}
+
+// groupJobsByParent groups the given list of jobs by their owning CronJob's UID.
+// Jobs without a CronJob owner are discarded, as they cannot be adopted.
+func groupJobsByParent(js []batchv1.Job) map[types.UID][]batchv1.Job {
+	jobsBySj := make(map[types.UID][]batchv1.Job)
+	for _, job := range js {
+		parentUID, found := getParentUIDFromJob(job)
+		if !found {
+			continue
+		}
+		jobsBySj[parentUID] = append(jobsBySj[parentUID], job)
+	}
+	return jobsBySj
+}
+
+// getRecentUnmetScheduleTimes gets a slice of times (from oldest to latest) that have passed when a Job should have
+// started and did not. If there are too many (>100) unstarted times, it will raise an error.
+func getRecentUnmetScheduleTimes(sj batchv1beta1.CronJob, now time.Time) ([]time.Time, error) {
+	starts := []time.Time{}
+	sched, err := cron.ParseStandard(sj.Spec.Schedule)
+	if err != nil {
+		return starts, fmt.Errorf("unparseable schedule: %s : %s", sj.Spec.Schedule, err)
+	}
+
+	var earliestTime time.Time
+	if sj.Status.LastScheduleTime != nil {
+		earliestTime = sj.Status.LastScheduleTime.Time
+	} else {
+		// If none found, then this is either a recently created cronJob, or the
+		// controller was recently restarted, or the controller failed to schedule.
+		// In this case, the CronJob needs to "catch up" starting from its creation time.
+		earliestTime = sj.ObjectMeta.CreationTimestamp.Time
+	}
+	if sj.Spec.StartingDeadlineSeconds != nil {
+		// Controller is not going to schedule anything below this point
+		schedulingDeadline := now.Add(-time.Second * time.Duration(*sj.Spec.StartingDeadlineSeconds))
+
+		if schedulingDeadline.After(earliestTime) {
+			earliestTime = schedulingDeadline
+		}
+	}
+	if earliestTime.After(now) {
+		return []time.Time{}, nil
+	}
+
+	for t := sched.Next(earliestTime); !t.After(now); t = sched.Next(t) {
+		starts = append(starts, t)
+		// An object might miss several starts. For example, if
+		// controller gets wedged on friday at 5:01pm when everyone has
+		// gone home, and someone comes in on monday at 9am and discovers
+		// the problem and restarts the controller, then all the hourly
+		// jobs, more than 80 of them for one hourly cronJob, should all
+		// start running with no further intervention (if the cronJob
+		// allows concurrency and late starts).
+		//
+		// However, if there is a bug somewhere, or incorrect clock
+		// on controller's server or apiservers (for setting
+		// CreationTimestamp) then there could be many, many missed
+		// start times. We don't want to rush the controller into a
+		// bunch of unnecessary processing if this happens.
+		if len(starts) > 100 {
+			// We can't get the most recent times so just return an empty
+			// slice
+			return []time.Time{}, fmt.Errorf("too many missed start times (> 100). Set or decrease .spec.startingDeadlineSeconds or check clock skew")
+		}
+	}
+	return starts, nil
+}
+
+// byJobStartTime sorts a list of Jobs by start timestamp, using their names as a tie breaker.
+type byJobStartTime []batchv1.Job
+
+func (o byJobStartTime) Len() int      { return len(o) }
+func (o byJobStartTime) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+
+func (o byJobStartTime) Less(i, j int) bool {
+	if o[i].Status.StartTime == nil && o[j].Status.StartTime != nil {
+		return false
+	}
+	if o[i].Status.StartTime != nil && o[j].Status.StartTime == nil {
+		return true
+	}
+	if o[i].Status.StartTime.Equal(o[j].Status.StartTime) {
+		return o[i].Name < o[j].Name
+	}
+	return o[i].Status.StartTime.Before(o[j].Status.StartTime)
+}
+
+// getLatestMissedSchedule walks forward one tick at a time from earliestTime looking for the
+// latest schedule time that is not after now. It is simple and correct, but linear in the
+// number of missed schedules, so getLatestMissedScheduleBinarySearch should be preferred when
+// the gap between earliestTime and now may be large.
+func getLatestMissedSchedule(earliestTime, now time.Time, schedule cron.Schedule) (time.Time, int64) {
+	var (
+		lastMissed   time.Time
+		numberMissed int64
+	)
+	for t := schedule.Next(earliestTime); !t.After(now); t = schedule.Next(t) {
+		lastMissed = t
+		numberMissed++
+	}
+	return lastMissed, numberMissed
+}
+
+// getLatestMissedScheduleBinarySearch finds the latest schedule time between earliestTime and
+// now using a doubling-then-binary-search strategy, so that CronJobs with a very dense schedule
+// (e.g. every minute) that have gone unscheduled for a long time don't force the controller to
+// iterate through every missed tick one at a time.
+func getLatestMissedScheduleBinarySearch(earliestTime, now time.Time, schedule cron.Schedule) (time.Time, bool) {
+	t1 := schedule.Next(earliestTime)
+	if t1.After(now) {
+		return time.Time{}, false
+	}
+
+	// Find an upper bound t2 (a schedule tick strictly after `now`) by doubling the search
+	// interval instead of stepping tick-by-tick.
+	interval := now.Sub(t1)
+	if interval <= 0 {
+		interval = time.Second
+	}
+	t2 := schedule.Next(t1.Add(interval))
+	for !t2.After(now) {
+		interval *= 2
+		t2 = schedule.Next(t1.Add(interval))
+	}
+
+	// Binary search the window (t1, t2) for the latest tick that is not after `now`: t1 is
+	// always a tick not after `now`, and every tick after t2 is after `now`.
+	for i := 0; i < 100 && !schedule.Next(t1).After(now); i++ {
+		mid := t1.Add(t2.Sub(t1) / 2)
+		if next := schedule.Next(mid); next.After(now) {
+			t2 = mid
+		} else {
+			t1 = next
+		}
+	}
+
+	return t1, true
+}
+
+func copyLabels(template *batchv1beta1.JobTemplateSpec) labelsMap {
+	l := labelsMap{}
+	for k, v := range template.Labels {
+		l[k] = v
+	}
+	return l
+}
+
+func copyAnnotations(template *batchv1beta1.JobTemplateSpec) labelsMap {
+	a := labelsMap{}
+	for k, v := range template.Annotations {
+		a[k] = v
+	}
+	return a
+}
+
+type labelsMap map[string]string
+
+func createJobControllerRef(sj *batchv1beta1.CronJob) *metav1.OwnerReference {
+	blockOwnerDeletion := true
+	isController := true
+	return &metav1.OwnerReference{
+		APIVersion:         batchv1beta1.SchemeGroupVersion.String(),
+		Kind:               "CronJob",
+		Name:               sj.Name,
+		UID:                sj.UID,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+		Controller:         &isController,
+	}
+}
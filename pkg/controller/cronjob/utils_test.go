@@ -92,6 +92,95 @@ func TestGetJobFromTemplate(t *testing.T) {
 	}
 }
 
+func TestGetJobFromTemplateJobMetadata(t *testing.T) {
+	scheduledTime, err := time.Parse(time.RFC3339, "2016-05-19T10:00:00Z")
+	if err != nil {
+		t.Fatalf("test setup error: %v", err)
+	}
+
+	newCronJob := func() batchv1beta1.CronJob {
+		return batchv1beta1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mycronjob",
+				Namespace: "snazzycats",
+				UID:       types.UID("1a2b3c"),
+			},
+			Spec: batchv1beta1.CronJobSpec{
+				Schedule:          "* * * * ?",
+				ConcurrencyPolicy: batchv1beta1.AllowConcurrent,
+				JobTemplate: batchv1beta1.JobTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels:      map[string]string{"a": "b"},
+						Annotations: map[string]string{"x": "y"},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("substitution", func(t *testing.T) {
+		sj := newCronJob()
+		sj.Spec.JobMetadata = &batchv1beta1.JobMetadata{
+			Labels: map[string]string{
+				"scheduled-time": "{{.ScheduledTime}}",
+				"cronjob-name":   "{{.CronJobName}}",
+			},
+			Annotations: map[string]string{
+				"cronjob-uid": "{{.CronJobUID}}",
+				"namespace":   "{{.Namespace}}",
+			},
+		}
+
+		job, err := getJobFromTemplate(&sj, scheduledTime)
+		if err != nil {
+			t.Fatalf("did not expect error: %v", err)
+		}
+		if got, want := job.Labels["scheduled-time"], scheduledTime.UTC().Format(time.RFC3339); got != want {
+			t.Errorf("scheduled-time label = %q, want %q", got, want)
+		}
+		if got, want := job.Labels["cronjob-name"], "mycronjob"; got != want {
+			t.Errorf("cronjob-name label = %q, want %q", got, want)
+		}
+		if got, want := job.Annotations["cronjob-uid"], "1a2b3c"; got != want {
+			t.Errorf("cronjob-uid annotation = %q, want %q", got, want)
+		}
+		if got, want := job.Annotations["namespace"], "snazzycats"; got != want {
+			t.Errorf("namespace annotation = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("JobTemplate takes precedence over JobMetadata", func(t *testing.T) {
+		sj := newCronJob()
+		sj.Spec.JobMetadata = &batchv1beta1.JobMetadata{
+			Labels:      map[string]string{"a": "should-not-win"},
+			Annotations: map[string]string{"x": "should-not-win"},
+		}
+
+		job, err := getJobFromTemplate(&sj, scheduledTime)
+		if err != nil {
+			t.Fatalf("did not expect error: %v", err)
+		}
+		if got, want := job.Labels["a"], "b"; got != want {
+			t.Errorf("label %q = %q, want %q (from JobTemplate)", "a", got, want)
+		}
+		if got, want := job.Annotations["x"], "y"; got != want {
+			t.Errorf("annotation %q = %q, want %q (from JobTemplate)", "x", got, want)
+		}
+	})
+
+	t.Run("reserved label keys are rejected", func(t *testing.T) {
+		for _, key := range []string{"controller-uid", "job-name"} {
+			sj := newCronJob()
+			sj.Spec.JobMetadata = &batchv1beta1.JobMetadata{
+				Labels: map[string]string{key: "anything"},
+			}
+			if _, err := getJobFromTemplate(&sj, scheduledTime); err == nil {
+				t.Errorf("expected error for reserved label key %q, got none", key)
+			}
+		}
+	})
+}
+
 func TestGetParentUIDFromJob(t *testing.T) {
 	j := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -469,6 +558,16 @@ func TestByJobStartTime(t *testing.T) {
 	}
 }
 
+// weekAfterTheHour returns a fixed point in time exactly a week after the on-the-hour
+// timestamp used as startTime throughout these tests, so it lands on an hourly tick itself.
+func weekAfterTheHour() time.Time {
+	startTime, err := time.Parse(time.RFC3339, "2016-05-19T10:00:00Z")
+	if err != nil {
+		panic("test setup error")
+	}
+	return startTime.Add(7 * 24 * time.Hour)
+}
+
 func Test_getLatestMissedSchedule(t *testing.T) {
 	startTime, err := time.Parse(time.RFC3339, "2016-05-19T10:00:00Z")
 	if err != nil {
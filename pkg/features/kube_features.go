@@ -0,0 +1,52 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/runtime"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/component-base/featuregate"
+)
+
+const (
+	// owner: @cronjob-controller
+	// alpha: v1.31
+	//
+	// Derives child Job names for CronJobs deterministically from the CronJob name and
+	// scheduled time, instead of appending a random suffix, so that replaying a scheduled
+	// time (e.g. after a controller crash) resolves to the same Job name and can be safely
+	// retried.
+	CronJobsDeterministicJobNames featuregate.Feature = "CronJobsDeterministicJobNames"
+
+	// owner: @cronjob-controller
+	// alpha: v1.31
+	//
+	// Enables CronJobSpec.DeletionPolicy and the cronjob-child-cleanup finalizer that
+	// enforces it for CronJobs that explicitly opt into a non-Orphan policy.
+	CronJobsDeletionPolicy featuregate.Feature = "CronJobsDeletionPolicy"
+)
+
+func init() {
+	runtime.Must(utilfeature.DefaultMutableFeatureGate.Add(defaultKubernetesFeatureGates))
+}
+
+// defaultKubernetesFeatureGates consists of all known Kubernetes-specific feature keys in this
+// package. To add a new feature, define a key for it above and add it here.
+var defaultKubernetesFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	CronJobsDeterministicJobNames: {Default: false, PreRelease: featuregate.Alpha},
+	CronJobsDeletionPolicy:        {Default: false, PreRelease: featuregate.Alpha},
+}
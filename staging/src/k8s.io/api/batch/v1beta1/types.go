@@ -0,0 +1,172 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CronJob represents the configuration of a single cron job.
+type CronJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Spec is a structure defining the expected behavior of a job, including the schedule.
+	// +optional
+	Spec CronJobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+
+	// Status is a structure describing current status of a job.
+	// +optional
+	Status CronJobStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CronJobList is a collection of cron jobs.
+type CronJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Items is the list of CronJobs.
+	Items []CronJob `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// ConcurrencyPolicy describes how the job will be handled.
+// Only one of the following concurrent policies may be specified.
+// If none of the following policies is specified, the default one is AllowConcurrent.
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent allows CronJobs to run concurrently.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+
+	// ForbidConcurrent forbids concurrent runs, skipping next run if previous run hasn't finished yet.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+
+	// ReplaceConcurrent cancels currently running job and replaces it with a new one.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// CronJobDeletionPolicy describes how a CronJob's child Jobs should be handled when the
+// CronJob itself is deleted.
+type CronJobDeletionPolicy string
+
+const (
+	// DeletionPolicyOrphan leaves cleanup of child Jobs to owner-reference garbage
+	// collection, the same behavior as before DeletionPolicy existed. This is the default
+	// when DeletionPolicy is unset.
+	DeletionPolicyOrphan CronJobDeletionPolicy = "Orphan"
+
+	// DeletionPolicyBackground deletes all child Jobs (with background propagation) before
+	// the CronJob itself is removed.
+	DeletionPolicyBackground CronJobDeletionPolicy = "Background"
+
+	// DeletionPolicyWaitForCompletion blocks removal of the CronJob until all of its
+	// currently running child Jobs have finished on their own; it does not delete any Jobs.
+	DeletionPolicyWaitForCompletion CronJobDeletionPolicy = "WaitForCompletion"
+)
+
+// JobMetadata holds labels and annotations that the cronjob controller merges onto every Job
+// it creates for a CronJob, in addition to JobTemplate.ObjectMeta. Values may reference the
+// scheduled invocation via a small template substitution vocabulary: {{.ScheduledTime}},
+// {{.CronJobName}}, {{.CronJobUID}} and {{.Namespace}}.
+type JobMetadata struct {
+	// Labels to add to each created Job, merged with (and losing precedence to)
+	// JobTemplate.ObjectMeta.Labels. May not set a label the controller itself manages,
+	// namely controller-uid and job-name.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" protobuf:"bytes,1,rep,name=labels"`
+
+	// Annotations to add to each created Job, merged with (and losing precedence to)
+	// JobTemplate.ObjectMeta.Annotations.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty" protobuf:"bytes,2,rep,name=annotations"`
+}
+
+// CronJobSpec describes how the job execution will look like and when it will actually run.
+type CronJobSpec struct {
+	// Schedule represents the schedule in Cron format, see https://en.wikipedia.org/wiki/Cron.
+	Schedule string `json:"schedule" protobuf:"bytes,1,opt,name=schedule"`
+
+	// Optional deadline in seconds for starting the job if it misses scheduled time for any
+	// reason. Missed jobs executions will be counted as failed ones.
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty" protobuf:"varint,2,opt,name=startingDeadlineSeconds"`
+
+	// Specifies how to treat concurrent executions of a Job.
+	// Valid values are:
+	// - "Allow" (default): allows CronJobs to run concurrently;
+	// - "Forbid": forbids concurrent runs, skipping next run if previous run hasn't finished yet;
+	// - "Replace": cancels currently running job and replaces it with a new one
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty" protobuf:"bytes,3,opt,name=concurrencyPolicy,casttype=ConcurrencyPolicy"`
+
+	// This flag tells the controller to suspend subsequent executions, it does
+	// not apply to already started executions. Defaults to false.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty" protobuf:"varint,4,opt,name=suspend"`
+
+	// Specifies the job that will be created when executing a CronJob.
+	JobTemplate JobTemplateSpec `json:"jobTemplate" protobuf:"bytes,5,opt,name=jobTemplate"`
+
+	// The number of successful finished jobs to retain.
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty" protobuf:"varint,6,opt,name=successfulJobsHistoryLimit"`
+
+	// The number of failed finished jobs to retain.
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty" protobuf:"varint,7,opt,name=failedJobsHistoryLimit"`
+
+	// DeletionPolicy controls what happens to this CronJob's child Jobs when the CronJob
+	// itself is deleted. Defaults to Orphan, which matches the pre-existing behavior of
+	// relying solely on owner-reference garbage collection. Only takes effect while the
+	// CronJobsDeletionPolicy feature gate is enabled.
+	// +optional
+	DeletionPolicy CronJobDeletionPolicy `json:"deletionPolicy,omitempty" protobuf:"bytes,8,opt,name=deletionPolicy,casttype=CronJobDeletionPolicy"`
+
+	// JobMetadata, when set, is merged onto every Job the controller creates for this
+	// CronJob, in addition to JobTemplate.ObjectMeta. It is purely additive and has no
+	// feature gate of its own.
+	// +optional
+	JobMetadata *JobMetadata `json:"jobMetadata,omitempty" protobuf:"bytes,9,opt,name=jobMetadata"`
+}
+
+// CronJobStatus represents the current state of a cron job.
+type CronJobStatus struct {
+	// A list of pointers to currently running jobs.
+	// +optional
+	Active []v1.ObjectReference `json:"active,omitempty" protobuf:"bytes,1,rep,name=active"`
+
+	// Information when was the last time the job was successfully scheduled.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty" protobuf:"bytes,4,opt,name=lastScheduleTime"`
+}
+
+// JobTemplateSpec describes the data a Job should have when created from a template.
+type JobTemplateSpec struct {
+	// Standard object's metadata of the jobs created from this template.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Specification of the desired behavior of the job.
+	// +optional
+	Spec batchv1.JobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}